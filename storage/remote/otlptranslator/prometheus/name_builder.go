@@ -0,0 +1,208 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/prometheus/prometheus/util/strutil"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// NameBuilder builds Prometheus-compliant metric names for a fixed
+// Normalizer configuration, with its regular expressions compiled once at
+// construction time instead of on every call. This matters on the OTLP
+// receive path, where thousands of metrics can arrive in a single request.
+//
+// Construct one with NewNameBuilder and reuse it across calls. A NameBuilder
+// does not mutate its own state, so it is safe for concurrent use as long as
+// the caller does not share the scratch slice passed to BuildBatch.
+type NameBuilder struct {
+	normalizer            Normalizer
+	invalidCharRE         *regexp.Regexp
+	multipleUnderscoresRE *regexp.Regexp
+}
+
+// NewNameBuilder constructs a NameBuilder for the given Normalizer,
+// compiling its regular expressions once up front.
+func NewNameBuilder(normalizer Normalizer) *NameBuilder {
+	return &NameBuilder{
+		normalizer:            normalizer,
+		invalidCharRE:         regexp.MustCompile(`[^a-zA-Z0-9:_]`),
+		multipleUnderscoresRE: regexp.MustCompile(`__+`),
+	}
+}
+
+// Build builds a normalized, Prometheus-compliant name for the specified
+// metric. It produces the same result as b.normalizer.Normalize, but reuses
+// b's precompiled regular expressions instead of compiling new ones on
+// every call.
+func (b *NameBuilder) Build(metric pmetric.Metric, namespace string) string {
+	tokens := make([]string, 0, 8)
+	return b.build(metric, namespace, tokens)
+}
+
+// BuildBatch builds a normalized name for every metric in metrics, writing
+// the results into out and returning it resliced to metrics.Len(). out is
+// grown if it does not have enough capacity, and is otherwise reused as-is,
+// so callers on a hot path can pass the same backing array in on every
+// call to avoid repeated allocation. A single token scratch slice is
+// likewise reused across all metrics in the batch.
+func (b *NameBuilder) BuildBatch(metrics pmetric.MetricSlice, namespace string, out []string) []string {
+	n := metrics.Len()
+	if cap(out) < n {
+		out = make([]string, n)
+	}
+	out = out[:n]
+
+	tokens := make([]string, 0, 8)
+	for i := 0; i < n; i++ {
+		out[i] = b.build(metrics.At(i), namespace, tokens)
+	}
+	return out
+}
+
+// SimpleName builds a Prometheus metric name without full normalization:
+// unsupported characters are replaced with underscores, but no unit or
+// type suffixes are added. It is the precompiled-regexp equivalent of
+// BuildCompliantName called with addMetricSuffixes=false, allowUTF8=false.
+func (b *NameBuilder) SimpleName(metric pmetric.Metric, namespace string) string {
+	sanitized := b.invalidCharRE.ReplaceAllString(metric.Name(), "_")
+	metricName := strings.Join(SplitMetricNameToTokens(sanitized, false), "_")
+
+	if namespace != "" {
+		return namespace + "_" + metricName
+	}
+
+	if metricName != "" && unicode.IsDigit(rune(metricName[0])) {
+		metricName = "_" + metricName
+	}
+
+	return metricName
+}
+
+// build is the shared implementation behind Build and BuildBatch. tokens is
+// scratch space for the metric name's tokens; its backing array is reused
+// but its contents are always overwritten before use.
+func (b *NameBuilder) build(metric pmetric.Metric, namespace string, tokens []string) string {
+	n := b.normalizer
+
+	if override, ok := n.Overrides[metric.Name()]; ok {
+		if namespace != "" {
+			return namespace + "_" + override
+		}
+		return override
+	}
+
+	if n.AllowUTF8 {
+		// UTF-8 names retain arbitrary separators, which the scratch-slice
+		// fast path below does not model; fall back to the general
+		// implementation.
+		return n.Normalize(metric, namespace)
+	}
+
+	sanitized := b.invalidCharRE.ReplaceAllString(metric.Name(), "_")
+	nameTokens := append(tokens[:0], SplitMetricNameToTokens(sanitized, false)...)
+
+	unitTokens := strings.SplitN(metric.Unit(), "/", 2)
+	if len(unitTokens) > 0 {
+		var mainUnitProm, perUnitProm string
+		mainUnitOTel := strings.TrimSpace(unitTokens[0])
+		if mainUnitOTel != "" {
+			// A UnitMap override is consulted even for brace-delimited units
+			// (e.g. "{requests}"); only once there is no override do such
+			// units get skipped entirely. See Normalizer.Normalize.
+			if override, ok := n.UnitMap[mainUnitOTel]; ok {
+				mainUnitProm = override
+			} else if !strings.ContainsAny(mainUnitOTel, "{}") {
+				mainUnitProm = mainUnitOTel
+			}
+			if mainUnitProm != "" {
+				mainUnitProm = b.cleanUpUnit(mainUnitProm)
+				if hasTokenCaseInsensitive(nameTokens, mainUnitProm) || endsWithUnitWord(nameTokens, mainUnitProm) {
+					mainUnitProm = ""
+				}
+			}
+		}
+
+		if len(unitTokens) > 1 && unitTokens[1] != "" {
+			perUnitOTel := strings.TrimSpace(unitTokens[1])
+			if perUnitOTel != "" && !strings.ContainsAny(perUnitOTel, "{}") {
+				perUnitProm = b.cleanUpUnit(mapGetOrDefault(n.PerUnitMap, perUnitOTel))
+			}
+			if perUnitProm != "" {
+				perUnitProm = "per_" + perUnitProm
+				if hasTokenCaseInsensitive(nameTokens, perUnitProm) {
+					perUnitProm = ""
+				}
+			}
+		}
+
+		if perUnitProm != "" {
+			mainUnitProm = strings.TrimSuffix(mainUnitProm, "_")
+		}
+		if mainUnitProm != "" {
+			nameTokens = append(nameTokens, mainUnitProm)
+		}
+		if perUnitProm != "" {
+			nameTokens = append(nameTokens, perUnitProm)
+		}
+	}
+
+	if n.AddTotalSuffix && metric.Type() == pmetric.MetricTypeSum && metric.Sum().IsMonotonic() {
+		nameTokens, _ = appendDedupedSuffix(nameTokens, "total")
+	}
+	if n.AddRatioSuffix && metric.Unit() == "1" && metric.Type() == pmetric.MetricTypeGauge {
+		nameTokens, _ = appendDedupedSuffix(nameTokens, "ratio")
+	}
+
+	if namespace != "" {
+		nameTokens = append([]string{namespace}, nameTokens...)
+	}
+
+	normalizedName := strings.Join(nameTokens, "_")
+	if normalizedName != "" && unicode.IsDigit(rune(normalizedName[0])) {
+		normalizedName = "_" + normalizedName
+	}
+
+	return normalizedName
+}
+
+// cleanUpUnit cleans up unit so it matches model.LabelNameRE, using b's
+// precompiled regular expression rather than compiling one per call.
+func (b *NameBuilder) cleanUpUnit(unit string) string {
+	return strings.TrimPrefix(b.multipleUnderscoresRE.ReplaceAllString(
+		strutil.SanitizeLabelName(unit),
+		"_",
+	), "_")
+}
+
+// defaultNameBuilder is the sync.Once-initialized NameBuilder backing the
+// package-level BuildCompliantName/normalizeName functions, so that callers
+// who don't need a custom Normalizer don't pay for regexp compilation on
+// every call.
+var (
+	defaultNameBuilderOnce sync.Once
+	defaultNameBuilder     *NameBuilder
+)
+
+func getDefaultNameBuilder() *NameBuilder {
+	defaultNameBuilderOnce.Do(func() {
+		defaultNameBuilder = NewNameBuilder(DefaultNormalizer)
+	})
+	return defaultNameBuilder
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestNameBuilderMatchesNormalizer(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("http.server.request.duration")
+	metric.SetUnit("s")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	b := NewNameBuilder(DefaultNormalizer)
+	require.Equal(t, DefaultNormalizer.Normalize(metric, "myapp"), b.Build(metric, "myapp"))
+}
+
+func TestNameBuilderSimpleNameMatchesBuildCompliantName(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("http.server.request_count")
+	metric.SetUnit("s")
+	metric.SetEmptyGauge()
+
+	want := BuildCompliantName(metric, "myapp", false, false)
+	require.Equal(t, want, NewNameBuilder(DefaultNormalizer).SimpleName(metric, "myapp"))
+	require.Equal(t, "myapp_http_server_request_count", want)
+}
+
+func TestNameBuilderCustomUnitMapOverridesBraceUnit(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("http_request_count")
+	metric.SetUnit("{req}")
+	metric.SetEmptyGauge()
+
+	withOverride := DefaultNormalizer
+	withOverride.UnitMap = map[string]string{"{req}": "requests"}
+
+	b := NewNameBuilder(withOverride)
+	require.Equal(t, "http_request_count_requests", b.Build(metric, ""))
+}
+
+func TestNameBuilderBuildBatch(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	names := []string{"cpu.usage", "disk.bytes.written", "request.count"}
+	for _, name := range names {
+		m := scopeMetrics.AppendEmpty()
+		m.SetName(name)
+		m.SetUnit("1")
+		m.SetEmptyGauge()
+	}
+
+	b := NewNameBuilder(DefaultNormalizer)
+	got := b.BuildBatch(scopeMetrics, "", nil)
+	require.Len(t, got, len(names))
+	for i := 0; i < scopeMetrics.Len(); i++ {
+		require.Equal(t, b.Build(scopeMetrics.At(i), ""), got[i])
+	}
+
+	// Reusing the same backing slice across calls must not corrupt earlier
+	// results; BuildBatch should overwrite it rather than alias it.
+	reused := got
+	got2 := b.BuildBatch(scopeMetrics, "ns", reused)
+	require.Equal(t, "ns_cpu_usage_ratio", got2[0])
+}
+
+func benchmarkMetrics(n int) pmetric.MetricSlice {
+	metrics := pmetric.NewMetrics()
+	scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for i := 0; i < n; i++ {
+		m := scopeMetrics.AppendEmpty()
+		m.SetName(fmt.Sprintf("http.server.request.duration.%d", i))
+		m.SetUnit("s")
+		m.SetEmptySum().SetIsMonotonic(true)
+	}
+	return scopeMetrics
+}
+
+// BenchmarkBuildCompliantName exercises BuildCompliantName's
+// addMetricSuffixes=true path. This is now backed by the cached
+// getDefaultNameBuilder(), not a per-call regexp.MustCompile; see
+// BenchmarkUncachedRegexpCompile for the cost that used to be paid here.
+func BenchmarkBuildCompliantName(b *testing.B) {
+	metrics := benchmarkMetrics(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < metrics.Len(); j++ {
+			BuildCompliantName(metrics.At(j), "myapp", true, false)
+		}
+	}
+}
+
+// BenchmarkUncachedRegexpCompile exercises the regexp.MustCompile-per-call
+// cost that getDefaultNameBuilder's sync.Once now avoids. BuildCompliantName
+// no longer takes this path itself, so this benchmarks the historical
+// baseline directly instead of through BuildCompliantName.
+func BenchmarkUncachedRegexpCompile(b *testing.B) {
+	metrics := benchmarkMetrics(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < metrics.Len(); j++ {
+			re := regexp.MustCompile(`[^a-zA-Z0-9:_]`)
+			_ = re.ReplaceAllString(metrics.At(j).Name(), "_")
+		}
+	}
+}
+
+// BenchmarkNameBuilderBuildBatch exercises the construct-once, scratch-slice
+// reusing path.
+func BenchmarkNameBuilderBuildBatch(b *testing.B) {
+	metrics := benchmarkMetrics(1000)
+	builder := NewNameBuilder(DefaultNormalizer)
+	out := make([]string, metrics.Len())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = builder.BuildBatch(metrics, "myapp", out)
+	}
+}
+
+// BenchmarkBuildCompliantNameNoSuffixes exercises BuildCompliantName's
+// addMetricSuffixes=false path, also now backed by the cached
+// getDefaultNameBuilder() via SimpleName rather than a per-call
+// regexp.MustCompile; see BenchmarkUncachedRegexpCompile for that cost.
+func BenchmarkBuildCompliantNameNoSuffixes(b *testing.B) {
+	metrics := benchmarkMetrics(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < metrics.Len(); j++ {
+			BuildCompliantName(metrics.At(j), "myapp", false, false)
+		}
+	}
+}
+
+// BenchmarkNameBuilderSimpleName exercises the precompiled-regexp
+// equivalent of BenchmarkBuildCompliantNameNoSuffixes.
+func BenchmarkNameBuilderSimpleName(b *testing.B) {
+	metrics := benchmarkMetrics(1000)
+	builder := NewNameBuilder(DefaultNormalizer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < metrics.Len(); j++ {
+			builder.SimpleName(metrics.At(j), "myapp")
+		}
+	}
+}
@@ -18,9 +18,7 @@ package prometheus
 
 import (
 	"regexp"
-	"slices"
 	"strings"
-	"unicode"
 
 	"github.com/prometheus/prometheus/util/strutil"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -47,22 +45,58 @@ var unitMap = map[string]string{
 	"MiBy": "mebibytes",
 	"GiBy": "gibibytes",
 	"TiBy": "tibibytes",
+	"PiBy": "pebibytes",
 	"KBy":  "kilobytes",
 	"MBy":  "megabytes",
 	"GBy":  "gigabytes",
 	"TBy":  "terabytes",
+	"PBy":  "petabytes",
+
+	// Bits
+	"Bit":  "bits",
+	"KBit": "kilobits",
+	"MBit": "megabits",
+	"GBit": "gigabits",
+	"TBit": "terabits",
 
 	// SI
-	"m": "meters",
-	"V": "volts",
-	"A": "amperes",
-	"J": "joules",
-	"W": "watts",
-	"g": "grams",
+	"m":  "meters",
+	"V":  "volts",
+	"A":  "amperes",
+	"J":  "joules",
+	"W":  "watts",
+	"g":  "grams",
+	"kg": "kilograms",
+	"mV": "millivolts",
+	"mA": "milliamps",
+	"mW": "milliwatts",
+	"km": "kilometers",
+	"cm": "centimeters",
+	"mm": "millimeters",
+
+	// Angle
+	"rad": "radians",
+	"deg": "degrees",
+
+	// Pressure
+	"Pa":  "pascals",
+	"hPa": "hectopascals",
+	"bar": "bars",
+
+	// Volume
+	"l":  "liters",
+	"L":  "liters",
+	"ml": "milliliters",
+	"mL": "milliliters",
+
+	// Frequency
+	"Hz":  "hertz",
+	"KHz": "kilohertz",
+	"MHz": "megahertz",
+	"GHz": "gigahertz",
 
 	// Misc
 	"Cel": "celsius",
-	"Hz":  "hertz",
 	"1":   "",
 	"%":   "percent",
 }
@@ -94,142 +128,46 @@ func BuildCompliantName(metric pmetric.Metric, namespace string, addMetricSuffix
 		return normalizeName(metric, namespace, allowUTF8)
 	}
 
-	var metricName string
+	// Simple case (no full normalization, no units, etc.). This is a thin
+	// wrapper over the sync.Once-initialized default NameBuilder, so it
+	// doesn't pay to recompile its regular expression on every call either.
 	if !allowUTF8 {
-		// Regexp for metric name characters that should be replaced with _.
-		invalidMetricCharRE := regexp.MustCompile(`[^a-zA-Z0-9:_]`)
-
-		// Simple case (no full normalization, no units, etc.).
-		metricName = strings.Join(strings.FieldsFunc(metric.Name(), func(r rune) bool {
-			return invalidMetricCharRE.MatchString(string(r))
-		}), "_")
-	} else {
-		metricName = metric.Name()
+		return getDefaultNameBuilder().SimpleName(metric, namespace)
 	}
 
-	// Namespace?
+	metricName := metric.Name()
 	if namespace != "" {
 		return namespace + "_" + metricName
 	}
-
-	// Metric name starts with a digit and utf8 not allowed? Prefix it with an underscore.
-	if metricName != "" && unicode.IsDigit(rune(metricName[0])) && !allowUTF8 {
-		metricName = "_" + metricName
-	}
-
 	return metricName
 }
 
-// Build a normalized name for the specified metric.
+// Build a normalized name for the specified metric, using the package
+// default unit tables and suffix rules. This is a thin wrapper over a
+// sync.Once-initialized default NameBuilder, so repeated calls don't pay to
+// recompile its regular expressions; see Normalizer.Normalize for the full,
+// configurable implementation and NameBuilder for the batch-oriented one.
 func normalizeName(metric pmetric.Metric, namespace string, allowUTF8 bool) string {
-	var nameTokens []string
-	var separators []string
-	if !allowUTF8 {
-		nonTokenMetricCharRE := regexp.MustCompile(`[^a-zA-Z0-9:]`)
-		// Split metric name into "tokens" (of supported metric name runes).
-		// Note that this has the side effect of replacing multiple consecutive underscores with a single underscore.
-		// This is part of the OTel to Prometheus specification: https://github.com/open-telemetry/opentelemetry-specification/blob/v1.38.0/specification/compatibility/prometheus_and_openmetrics.md#otlp-metric-points-to-prometheus.
-		nameTokens = strings.FieldsFunc(
-			metric.Name(),
-			func(r rune) bool { return nonTokenMetricCharRE.MatchString(string(r)) },
-		)
-	} else {
-		translationFunc := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ':' }
-		// Split metric name into "tokens" (of supported metric name runes).
-		nameTokens, separators = fieldsFunc(metric.Name(), translationFunc)
-	}
-
-	// Split unit at the '/' if any
-	unitTokens := strings.SplitN(metric.Unit(), "/", 2)
-
-	// Main unit
-	// Append if not blank, doesn't contain '{}', and is not present in metric name already
-	if len(unitTokens) > 0 {
-		var mainUnitProm, perUnitProm string
-		mainUnitOTel := strings.TrimSpace(unitTokens[0])
-		if mainUnitOTel != "" && !strings.ContainsAny(mainUnitOTel, "{}") {
-			mainUnitProm = unitMapGetOrDefault(mainUnitOTel)
-			if !allowUTF8 {
-				mainUnitProm = cleanUpUnit(mainUnitProm)
-			}
-			if slices.Contains(nameTokens, mainUnitProm) {
-				mainUnitProm = ""
-			}
-		}
-
-		// Per unit
-		// Append if not blank, doesn't contain '{}', and is not present in metric name already
-		if len(unitTokens) > 1 && unitTokens[1] != "" {
-			perUnitOTel := strings.TrimSpace(unitTokens[1])
-			if perUnitOTel != "" && !strings.ContainsAny(perUnitOTel, "{}") {
-				perUnitProm = perUnitMapGetOrDefault(perUnitOTel)
-				if !allowUTF8 {
-					perUnitProm = cleanUpUnit(perUnitProm)
-				}
-			}
-			if perUnitProm != "" {
-				perUnitProm = "per_" + perUnitProm
-				if slices.Contains(nameTokens, perUnitProm) {
-					perUnitProm = ""
-				}
-			}
-		}
-
-		if perUnitProm != "" {
-			mainUnitProm = strings.TrimSuffix(mainUnitProm, "_")
-		}
-
-		if mainUnitProm != "" {
-			nameTokens = append(nameTokens, mainUnitProm)
-		}
-		if perUnitProm != "" {
-			nameTokens = append(nameTokens, perUnitProm)
-		}
-	}
-
-	// Append _total for Counters
-	if metric.Type() == pmetric.MetricTypeSum && metric.Sum().IsMonotonic() {
-		nameTokens = append(removeItem(nameTokens, "total"), "total")
-	}
-
-	// Append _ratio for metrics with unit "1"
-	// Some OTel receivers improperly use unit "1" for counters of objects
-	// See https://github.com/open-telemetry/opentelemetry-collector-contrib/issues?q=is%3Aissue+some+metric+units+don%27t+follow+otel+semantic+conventions
-	// Until these issues have been fixed, we're appending `_ratio` for gauges ONLY
-	// Theoretically, counters could be ratios as well, but it's absurd (for mathematical reasons)
-	if metric.Unit() == "1" && metric.Type() == pmetric.MetricTypeGauge {
-		nameTokens = append(removeItem(nameTokens, "ratio"), "ratio")
+	if allowUTF8 {
+		n := DefaultNormalizer
+		n.AllowUTF8 = true
+		return n.Normalize(metric, namespace)
 	}
-
-	// Namespace?
-	if namespace != "" {
-		nameTokens = append([]string{namespace}, nameTokens...)
-	}
-
-	var normalizedName string
-	if !allowUTF8 {
-		// Build the string from the tokens, separated with underscores
-		normalizedName = strings.Join(nameTokens, "_")
-	} else {
-		// Build the string from the tokens + separators.
-		normalizedName = join(nameTokens, separators, "_")
-	}
-
-	// Metric name cannot start with a digit, so prefix it with "_" in this case
-	if normalizedName != "" && unicode.IsDigit(rune(normalizedName[0])) {
-		normalizedName = "_" + normalizedName
-	}
-
-	return normalizedName
+	return getDefaultNameBuilder().Build(metric, namespace)
 }
 
 // TrimPromSuffixes trims type and unit prometheus suffixes from a metric name.
 // Following the [OpenTelemetry specs] for converting Prometheus Metric points to OTLP.
 //
+// Tokenization keeps empty tokens (keepEmpty=true), so a doubled separator in
+// the input (e.g. "foo__bar_total") round-trips as "foo__bar" rather than
+// silently collapsing to "foo_bar"; only the recognized trailing suffix is
+// removed.
+//
 // [OpenTelemetry specs]: https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/metrics/data-model.md#metric-metadata
 func TrimPromSuffixes(promName string, metricType pmetric.MetricType, unit string) string {
-	nameTokens := strings.Split(promName, "_")
-	if len(nameTokens) == 1 {
+	nameTokens := SplitMetricNameToTokens(promName, true)
+	if len(nameTokens) <= 1 {
 		return promName
 	}
 
@@ -251,17 +189,21 @@ func removeTypeSuffixes(tokens []string, metricType pmetric.MetricType) []string
 }
 
 func removeUnitSuffixes(nameTokens []string, unit string) []string {
+	if unit == "" {
+		return nameTokens
+	}
+
 	l := len(nameTokens)
-	unitTokens := strings.Split(unit, "_")
+	unitTokens := SplitMetricNameToTokens(unit, true)
 	lu := len(unitTokens)
 
-	if lu == 0 || l <= lu {
+	if l <= lu {
 		return nameTokens
 	}
 
 	suffixed := true
 	for i := range unitTokens {
-		if nameTokens[l-i-1] != unitTokens[lu-i-1] {
+		if !strings.EqualFold(nameTokens[l-i-1], unitTokens[lu-i-1]) {
 			suffixed = false
 			break
 		}
@@ -276,7 +218,7 @@ func removeUnitSuffixes(nameTokens []string, unit string) []string {
 
 func removeSuffix(tokens []string, suffix string) []string {
 	l := len(tokens)
-	if tokens[l-1] == suffix {
+	if strings.EqualFold(tokens[l-1], suffix) {
 		return tokens[:l-1]
 	}
 
@@ -294,22 +236,28 @@ func cleanUpUnit(unit string) string {
 	), "_")
 }
 
-// Retrieve the Prometheus "basic" unit corresponding to the specified "basic" unit
-// Returns the specified unit if not found in unitMap
-func unitMapGetOrDefault(unit string) string {
-	if promUnit, ok := unitMap[unit]; ok {
-		return promUnit
+// endsWithUnitWord reports whether the metric name already ends with the
+// given unit's word form, so that appending it again would be redundant
+// (e.g. "disk_bytes_written" already ends in "bytes"). The comparison is
+// case-insensitive and ignores a trailing plural "s" on either side, so
+// "byte" and "bytes" are treated as the same word.
+func endsWithUnitWord(nameTokens []string, unitWord string) bool {
+	if len(nameTokens) == 0 || unitWord == "" {
+		return false
 	}
-	return unit
+	last := strings.ToLower(nameTokens[len(nameTokens)-1])
+	unitWord = strings.ToLower(unitWord)
+	return strings.TrimSuffix(last, "s") == strings.TrimSuffix(unitWord, "s")
 }
 
-// Retrieve the Prometheus "per" unit corresponding to the specified "per" unit
-// Returns the specified unit if not found in perUnitMap
-func perUnitMapGetOrDefault(perUnit string) string {
-	if promPerUnit, ok := perUnitMap[perUnit]; ok {
-		return promPerUnit
+// mapGetOrDefault looks up key in m, returning key itself if absent. It
+// backs both unit and per-unit translation, which fall back to the raw OTel
+// unit when it has no entry in the configured map.
+func mapGetOrDefault(m map[string]string, key string) string {
+	if value, ok := m[key]; ok {
+		return value
 	}
-	return perUnit
+	return key
 }
 
 // Remove the specified value from the slice
@@ -322,3 +270,28 @@ func removeItem(slice []string, value string) []string {
 	}
 	return newSlice
 }
+
+// appendDedupedSuffix removes any existing occurrence of suffix from
+// nameTokens and appends a single instance at the end, so a metric name
+// that already contains e.g. "total" doesn't end up as "..._total_total".
+// changed reports whether this actually reordered nameTokens (as opposed to
+// suffix already being the sole, trailing token), which callers preserving
+// positional separator data need to know: a reorder invalidates any
+// separator captured for the old positions.
+func appendDedupedSuffix(nameTokens []string, suffix string) (merged []string, changed bool) {
+	deduped := removeItem(nameTokens, suffix)
+	merged = append(deduped, suffix)
+	return merged, !stringSlicesEqual(merged, nameTokens)
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
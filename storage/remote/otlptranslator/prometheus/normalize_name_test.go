@@ -0,0 +1,152 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name       string
+		metricName string
+		unit       string
+		metricType pmetric.MetricType
+		monotonic  bool
+		expected   string
+	}{
+		{
+			name:       "unit already present as a name token is not duplicated",
+			metricName: "disk_bytes_written",
+			unit:       "By",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "disk_bytes_written",
+		},
+		{
+			name:       "unit already present in singular form is not duplicated",
+			metricName: "disk_byte",
+			unit:       "By",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "disk_byte",
+		},
+		{
+			name:       "unit not present is appended",
+			metricName: "disk_written",
+			unit:       "By",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "disk_written_bytes",
+		},
+		{
+			name:       "prefixed byte unit",
+			metricName: "memory_usage",
+			unit:       "KiBy",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "memory_usage_kibibytes",
+		},
+		{
+			name:       "ratio unit on gauge",
+			metricName: "cache_hit",
+			unit:       "1",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "cache_hit_ratio",
+		},
+		{
+			name:       "ratio unit on sum does not get _ratio suffix",
+			metricName: "requests",
+			unit:       "1",
+			metricType: pmetric.MetricTypeSum,
+			monotonic:  true,
+			expected:   "requests_total",
+		},
+		{
+			name:       "non-monotonic sum gets no _total suffix",
+			metricName: "queue_size",
+			unit:       "1",
+			metricType: pmetric.MetricTypeSum,
+			monotonic:  false,
+			expected:   "queue_size",
+		},
+		{
+			name:       "monotonic sum with unit gets both unit and _total suffixes",
+			metricName: "requests",
+			unit:       "{requests}",
+			metricType: pmetric.MetricTypeSum,
+			monotonic:  true,
+			expected:   "requests_total",
+		},
+		{
+			name:       "angular unit",
+			metricName: "rotation",
+			unit:       "deg",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "rotation_degrees",
+		},
+		{
+			name:       "pressure unit",
+			metricName: "tire_pressure",
+			unit:       "bar",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "tire_pressure_bars",
+		},
+		{
+			name:       "frequency unit",
+			metricName: "signal",
+			unit:       "MHz",
+			metricType: pmetric.MetricTypeGauge,
+			expected:   "signal_megahertz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := pmetric.NewMetrics()
+			metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+			metric.SetName(tt.metricName)
+			metric.SetUnit(tt.unit)
+			switch tt.metricType {
+			case pmetric.MetricTypeSum:
+				sum := metric.SetEmptySum()
+				sum.SetIsMonotonic(tt.monotonic)
+			default:
+				metric.SetEmptyGauge()
+			}
+
+			require.Equal(t, tt.expected, normalizeName(metric, "", false))
+		})
+	}
+}
+
+func TestNormalizeNameAllowUTF8(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("café.日本語")
+	metric.SetUnit("s")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	require.Equal(t, "café.日本語_seconds_total", normalizeName(metric, "", true))
+}
+
+func TestBuildCompliantNameAllowUTF8NoSuffixes(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("café.日本語")
+	metric.SetUnit("s")
+	metric.SetEmptyGauge()
+
+	require.Equal(t, "café.日本語", BuildCompliantName(metric, "", false, true))
+	require.Equal(t, "ns_café.日本語", BuildCompliantName(metric, "ns", false, true))
+}
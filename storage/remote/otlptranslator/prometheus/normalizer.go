@@ -0,0 +1,222 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Normalizer builds Prometheus-compliant metric names from OTLP metrics,
+// using configurable unit and per-unit translation tables plus an optional
+// set of metric name overrides. Operators that need domain-specific units
+// (e.g. "req", "conn", "evt") or a pinned translation for a specific metric
+// can construct their own Normalizer instead of forking this package.
+//
+// TODO(otlp config loading): the motivating use case for this type is
+// populating it from a running server's `otlp:` config section, so operators
+// can configure unit overrides without forking this package. That wiring
+// does not exist yet anywhere in the tree - only this struct does. Whoever
+// owns the `otlp:` config section needs to add a loader that constructs a
+// Normalizer from it; until then, callers outside this package's tests can
+// only construct one directly, as shown by DefaultNormalizer below.
+//
+// The zero value is not usable; start from DefaultNormalizer and override
+// only the fields that need to change.
+type Normalizer struct {
+	// UnitMap translates OTel/UCUM unit strings to their Prometheus word
+	// form (e.g. "By" -> "bytes"). A unit absent from the map is used
+	// as-is.
+	UnitMap map[string]string
+
+	// PerUnitMap translates the "per" component of an OTel unit to its
+	// Prometheus word form (e.g. "s" -> "second"). A unit absent from the
+	// map is used as-is.
+	PerUnitMap map[string]string
+
+	// Overrides maps an OTel metric name directly to the Prometheus name
+	// that should be used for it, bypassing unit/suffix normalization
+	// entirely.
+	Overrides map[string]string
+
+	// AddTotalSuffix appends "_total" to monotonic sums.
+	AddTotalSuffix bool
+
+	// AddRatioSuffix appends "_ratio" to gauges with unit "1".
+	AddRatioSuffix bool
+
+	// AllowUTF8 allows UTF-8 characters in the produced metric name
+	// instead of replacing unsupported characters with underscores.
+	AllowUTF8 bool
+}
+
+// DefaultNormalizer is the Normalizer used by BuildCompliantName and
+// normalizeName; it reproduces the historical, non-configurable behavior of
+// this package.
+var DefaultNormalizer = Normalizer{
+	UnitMap:        unitMap,
+	PerUnitMap:     perUnitMap,
+	AddTotalSuffix: true,
+	AddRatioSuffix: true,
+}
+
+// Normalize builds a normalized, Prometheus-compliant name for the
+// specified metric: it applies n.Overrides first, then OTel-to-Prometheus
+// unit translation, then the _total/_ratio suffix rules configured on n.
+func (n Normalizer) Normalize(metric pmetric.Metric, namespace string) string {
+	if override, ok := n.Overrides[metric.Name()]; ok {
+		if namespace != "" {
+			return namespace + "_" + override
+		}
+		return override
+	}
+
+	var nameTokens []string
+	var separators []string
+	if !n.AllowUTF8 {
+		nonTokenMetricCharRE := regexp.MustCompile(`[^a-zA-Z0-9:_]`)
+		// Replace each unsupported rune with an underscore, then split on
+		// "_" via SplitMetricNameToTokens with keepEmpty=false, collapsing
+		// runs of consecutive separators (e.g. the double underscore in
+		// "foo__bar") into a single token boundary. This is part of the
+		// OTel to Prometheus specification: https://github.com/open-telemetry/opentelemetry-specification/blob/v1.38.0/specification/compatibility/prometheus_and_openmetrics.md#otlp-metric-points-to-prometheus.
+		sanitized := nonTokenMetricCharRE.ReplaceAllString(metric.Name(), "_")
+		nameTokens = SplitMetricNameToTokens(sanitized, false)
+	} else {
+		translationFunc := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ':' }
+		// Split metric name into "tokens" (of supported metric name runes).
+		nameTokens, separators = fieldsFunc(metric.Name(), translationFunc)
+	}
+
+	// Split unit at the '/' if any
+	unitTokens := strings.SplitN(metric.Unit(), "/", 2)
+
+	// Main unit
+	// Append if not blank, doesn't contain '{}', and is not present in metric name already
+	if len(unitTokens) > 0 {
+		var mainUnitProm, perUnitProm string
+		mainUnitOTel := strings.TrimSpace(unitTokens[0])
+		if mainUnitOTel != "" {
+			// A UnitMap override is consulted even for brace-delimited units
+			// (e.g. "{requests}"), so operators can teach this package about
+			// units OTel leaves untranslated by convention. Only once there
+			// is no override do brace-delimited units get skipped entirely.
+			if override, ok := n.UnitMap[mainUnitOTel]; ok {
+				mainUnitProm = override
+			} else if !strings.ContainsAny(mainUnitOTel, "{}") {
+				mainUnitProm = mainUnitOTel
+			}
+			if mainUnitProm != "" {
+				if !n.AllowUTF8 {
+					mainUnitProm = cleanUpUnit(mainUnitProm)
+				}
+				if hasTokenCaseInsensitive(nameTokens, mainUnitProm) || endsWithUnitWord(nameTokens, mainUnitProm) {
+					mainUnitProm = ""
+				}
+			}
+		}
+
+		// Per unit
+		// Append if not blank, doesn't contain '{}', and is not present in metric name already
+		if len(unitTokens) > 1 && unitTokens[1] != "" {
+			perUnitOTel := strings.TrimSpace(unitTokens[1])
+			if perUnitOTel != "" && !strings.ContainsAny(perUnitOTel, "{}") {
+				perUnitProm = mapGetOrDefault(n.PerUnitMap, perUnitOTel)
+				if !n.AllowUTF8 {
+					perUnitProm = cleanUpUnit(perUnitProm)
+				}
+			}
+			if perUnitProm != "" {
+				perUnitProm = "per_" + perUnitProm
+				if hasTokenCaseInsensitive(nameTokens, perUnitProm) {
+					perUnitProm = ""
+				}
+			}
+		}
+
+		if perUnitProm != "" {
+			mainUnitProm = strings.TrimSuffix(mainUnitProm, "_")
+		}
+
+		if mainUnitProm != "" {
+			nameTokens = append(nameTokens, mainUnitProm)
+		}
+		if perUnitProm != "" {
+			nameTokens = append(nameTokens, perUnitProm)
+		}
+	}
+
+	// Append _total for Counters
+	if n.AddTotalSuffix && metric.Type() == pmetric.MetricTypeSum && metric.Sum().IsMonotonic() {
+		var changed bool
+		nameTokens, changed = appendDedupedSuffix(nameTokens, "total")
+		if n.AllowUTF8 && changed {
+			// Dedup actually reordered nameTokens, which shifts every
+			// later token's position relative to the original separators;
+			// that pairing can no longer be trusted, so fall back to "_"
+			// everywhere rather than risk misattaching a stale separator
+			// to the wrong boundary.
+			separators = nil
+		}
+	}
+
+	// Append _ratio for metrics with unit "1"
+	// Some OTel receivers improperly use unit "1" for counters of objects
+	// See https://github.com/open-telemetry/opentelemetry-collector-contrib/issues?q=is%3Aissue+some+metric+units+don%27t+follow+otel+semantic+conventions
+	// Until these issues have been fixed, we're appending `_ratio` for gauges ONLY
+	// Theoretically, counters could be ratios as well, but it's absurd (for mathematical reasons)
+	if n.AddRatioSuffix && metric.Unit() == "1" && metric.Type() == pmetric.MetricTypeGauge {
+		var changed bool
+		nameTokens, changed = appendDedupedSuffix(nameTokens, "ratio")
+		if n.AllowUTF8 && changed {
+			separators = nil
+		}
+	}
+
+	// Namespace? Prepended directly into nameTokens only on the non-UTF8
+	// path: on the UTF8 path, separators is positional (separators[i] is
+	// the run between nameTokens[i] and nameTokens[i+1]), so splicing a
+	// namespace token in ahead of it would shift every pairing by one.
+	if namespace != "" && !n.AllowUTF8 {
+		nameTokens = append([]string{namespace}, nameTokens...)
+	}
+
+	var normalizedName string
+	if !n.AllowUTF8 {
+		// Build the string from the tokens, separated with underscores
+		normalizedName = strings.Join(nameTokens, "_")
+	} else {
+		// Build the string from the tokens + original separators, falling
+		// back to "_" for any boundary introduced after tokenization (e.g.
+		// an appended unit or _total/_ratio suffix).
+		normalizedName = join(nameTokens, separators, "_")
+		if namespace != "" {
+			if normalizedName == "" {
+				normalizedName = namespace
+			} else {
+				normalizedName = namespace + "_" + normalizedName
+			}
+		}
+	}
+
+	// Metric name cannot start with a digit, so prefix it with "_" in this case
+	if normalizedName != "" && unicode.IsDigit(rune(normalizedName[0])) {
+		normalizedName = "_" + normalizedName
+	}
+
+	return normalizedName
+}
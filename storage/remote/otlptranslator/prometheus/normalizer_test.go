@@ -0,0 +1,137 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestNormalizerCustomUnitMap(t *testing.T) {
+	newMetric := func() pmetric.Metric {
+		metrics := pmetric.NewMetrics()
+		metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("http_request_count")
+		metric.SetUnit("{req}")
+		metric.SetEmptyGauge()
+		return metric
+	}
+
+	// Without an override, "{req}" is a brace-delimited unit with no
+	// built-in translation, so it's dropped and the name is untouched.
+	require.Equal(t, "http_request_count", DefaultNormalizer.Normalize(newMetric(), ""))
+
+	// With an override, "{req}" -> "requests" must be consulted even
+	// though the unit is brace-delimited, appending the suffix.
+	withOverride := DefaultNormalizer
+	withOverride.UnitMap = map[string]string{"{req}": "requests"}
+	require.Equal(t, "http_request_count_requests", withOverride.Normalize(newMetric(), ""))
+}
+
+func TestNormalizerAllowUTF8PreservesSeparators(t *testing.T) {
+	// With AllowUTF8, the name's own non-ASCII separators (here "." between
+	// unicode letters) are preserved rather than collapsed to "_"; only
+	// newly appended tokens (the unit and _total suffix) pick up "_".
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("café.日本語")
+	metric.SetUnit("s")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	n := DefaultNormalizer
+	n.AllowUTF8 = true
+
+	require.Equal(t, "café.日本語_seconds_total", n.Normalize(metric, ""))
+	require.Equal(t, "ns_café.日本語_seconds_total", n.Normalize(metric, "ns"))
+}
+
+func TestNormalizerAllowUTF8EmptyNameWithNamespace(t *testing.T) {
+	// A name built entirely from separator runes normalizes to zero tokens;
+	// the namespace must not pick up a trailing underscore in that case.
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("...")
+	metric.SetUnit("")
+	metric.SetEmptyGauge()
+
+	n := DefaultNormalizer
+	n.AllowUTF8 = true
+
+	require.Equal(t, "ns", n.Normalize(metric, "ns"))
+}
+
+func TestNormalizerAllowUTF8DedupDoesNotMisalignSeparators(t *testing.T) {
+	// The name already contains a literal "total" token, which the _total
+	// suffix logic dedupes by removing it from the middle and re-appending
+	// at the end. That shifts every later token's position, so the
+	// original separators can no longer be trusted for this name; the
+	// result must fall back to "_" everywhere rather than reattach a
+	// stale "." to the wrong boundary.
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("total.foo.bar")
+	metric.SetUnit("")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	n := DefaultNormalizer
+	n.AllowUTF8 = true
+
+	require.Equal(t, "foo_bar_total", n.Normalize(metric, ""))
+}
+
+func TestNormalizerAllowUTF8DedupNoOpPreservesSeparators(t *testing.T) {
+	// "total" is already the trailing token, so dedup-and-reappend is a
+	// no-op reordering-wise; the original separator must still be used.
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("b.total")
+	metric.SetUnit("")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	n := DefaultNormalizer
+	n.AllowUTF8 = true
+
+	require.Equal(t, "b.total", n.Normalize(metric, ""))
+}
+
+func TestNormalizerOverride(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("http.server.duration")
+	metric.SetUnit("ms")
+	metric.SetEmptyGauge()
+
+	n := DefaultNormalizer
+	n.Overrides = map[string]string{"http.server.duration": "http_request_duration_seconds"}
+
+	require.Equal(t, "http_request_duration_seconds", n.Normalize(metric, ""))
+	require.Equal(t, "ns_http_request_duration_seconds", n.Normalize(metric, "ns"))
+}
+
+func TestNormalizerDisableSuffixes(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("requests")
+	metric.SetUnit("1")
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+
+	n := DefaultNormalizer
+	n.AddTotalSuffix = false
+	n.AddRatioSuffix = false
+
+	require.Equal(t, "requests", n.Normalize(metric, ""))
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import "strings"
+
+// SplitMetricNameToTokens splits a metric name into tokens on "_"
+// boundaries, the separator Prometheus metric-name suffixes use.
+//
+// Unlike strings.FieldsFunc with an "is underscore" predicate, which drops
+// empty fields and so collapses runs of consecutive underscores into a
+// single boundary, SplitMetricNameToTokens preserves those empty tokens
+// when keepEmpty is true. This matters when building or trimming unit
+// suffixes: silently collapsing "foo__bar" down to "foo_bar" loses
+// information and can make the OTel<->Prometheus name translation lossy.
+func SplitMetricNameToTokens(name string, keepEmpty bool) []string {
+	tokens := strings.Split(name, "_")
+	if keepEmpty {
+		return tokens
+	}
+
+	out := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token != "" {
+			out = append(out, token)
+		}
+	}
+	return out
+}
+
+// hasTokenCaseInsensitive reports whether tokens contains word, comparing
+// case-insensitively, so that an existing "Total" or "Bytes" token is
+// recognized as already satisfying the corresponding suffix rule.
+func hasTokenCaseInsensitive(tokens []string, word string) bool {
+	for _, token := range tokens {
+		if strings.EqualFold(token, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsFunc splits s into fields around runs of runes for which f returns
+// true, like strings.FieldsFunc, but also returns the literal separator run
+// between each pair of adjacent fields (leading/trailing separator runs are
+// dropped, same as strings.FieldsFunc drops the empty fields they'd produce).
+// len(seps) == len(fields)-1 whenever len(fields) > 0. This is what lets the
+// AllowUTF8 naming path preserve a metric's original non-ASCII separators
+// (e.g. "." or "::") instead of collapsing everything to "_".
+func fieldsFunc(s string, f func(rune) bool) (fields, seps []string) {
+	var field, sep strings.Builder
+	inField := false
+	for _, r := range s {
+		if f(r) {
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+			sep.WriteRune(r)
+			continue
+		}
+		if !inField && sep.Len() > 0 && len(fields) > 0 {
+			seps = append(seps, sep.String())
+		}
+		sep.Reset()
+		field.WriteRune(r)
+		inField = true
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, seps
+}
+
+// join reconstructs a name from fields and the separators between them,
+// pairing fields[i] and fields[i+1] with seps[i]. It falls back to
+// defaultSep for any boundary beyond len(seps), which happens when fields
+// has had tokens appended after the separators were captured (e.g. a unit or
+// _total/_ratio suffix) - those are always joined with a plain underscore
+// regardless of the name's original separator style.
+func join(fields, seps []string, defaultSep string) string {
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			if i-1 < len(seps) {
+				b.WriteString(seps[i-1])
+			} else {
+				b.WriteString(defaultSep)
+			}
+		}
+		b.WriteString(field)
+	}
+	return b.String()
+}
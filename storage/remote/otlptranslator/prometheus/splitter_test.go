@@ -0,0 +1,128 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSplitMetricNameToTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		keepEmpty bool
+		expected  []string
+	}{
+		{
+			name:     "single underscore separator",
+			input:    "foo_bar",
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name:      "double underscore collapsed by default",
+			input:     "foo__bar",
+			keepEmpty: false,
+			expected:  []string{"foo", "bar"},
+		},
+		{
+			name:      "double underscore preserved when opted in",
+			input:     "foo__bar",
+			keepEmpty: true,
+			expected:  []string{"foo", "", "bar"},
+		},
+		{
+			name:     "no separators",
+			input:    "foobar",
+			expected: []string{"foobar"},
+		},
+		{
+			name:     "unicode letters",
+			input:    "café_日本語_bar",
+			expected: []string{"café", "日本語", "bar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, SplitMetricNameToTokens(tt.input, tt.keepEmpty))
+		})
+	}
+}
+
+func TestNormalizeNameCollapsesDoubleUnderscore(t *testing.T) {
+	// Per the OTel-to-Prometheus spec, consecutive underscores collapse to
+	// a single token boundary instead of being preserved.
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("foo__bar")
+	metric.SetUnit("By")
+	metric.SetEmptyGauge()
+
+	require.Equal(t, "foo_bar_bytes", normalizeName(metric, "", false))
+}
+
+func TestNormalizeNameLeadingDigit(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("2xx_responses")
+	metric.SetUnit("1")
+	metric.SetEmptyGauge()
+
+	require.Equal(t, "_2xx_responses_ratio", normalizeName(metric, "", false))
+}
+
+func TestNormalizeNameAllInvalidCharacters(t *testing.T) {
+	// A name made up entirely of invalid characters sanitizes down to
+	// nothing but separators, which all collapse away.
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("!!!")
+	metric.SetUnit("")
+	metric.SetEmptyGauge()
+
+	require.Equal(t, "", normalizeName(metric, "", false))
+}
+
+func TestNormalizeNameUnitTokenCaseInsensitive(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("disk_Bytes_written")
+	metric.SetUnit("By")
+	metric.SetEmptyGauge()
+
+	require.Equal(t, "disk_Bytes_written", normalizeName(metric, "", false))
+}
+
+func TestTrimPromSuffixesTotalMidName(t *testing.T) {
+	// "total" appearing mid-name (not as the trailing type suffix) must
+	// not be stripped.
+	require.Equal(t, "total_requests", TrimPromSuffixes("total_requests", pmetric.MetricTypeGauge, ""))
+}
+
+func TestTrimPromSuffixesPreservesDoubleUnderscore(t *testing.T) {
+	// Stripping a recognized suffix must not collapse an unrelated doubled
+	// separator elsewhere in the name: only the "total" token is removed.
+	require.Equal(t, "foo__bar", TrimPromSuffixes("foo__bar_total", pmetric.MetricTypeSum, ""))
+}
+
+func TestTrimPromSuffixesAllInvalidCharacters(t *testing.T) {
+	// Names built entirely from invalid characters (e.g. "___", the result
+	// of normalizing "!!!") split into zero non-empty tokens; this must not
+	// panic on the empty- and single-token guard.
+	require.Equal(t, "___", TrimPromSuffixes("___", pmetric.MetricTypeGauge, ""))
+	require.Equal(t, "", TrimPromSuffixes("", pmetric.MetricTypeGauge, ""))
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Options controls how a Translator converts between Prometheus and OTLP
+// metric names.
+type Options struct {
+	// AddMetricSuffixes enables the addition of type and unit suffixes,
+	// following the OTLP-to-Prometheus naming rules. It has no effect when
+	// UsePrometheusNaming is set.
+	AddMetricSuffixes bool
+
+	// UsePrometheusNaming skips OTLP normalization entirely and passes the
+	// metric name through as-is, aside from namespacing.
+	UsePrometheusNaming bool
+
+	// AllowUTF8 allows UTF-8 characters in the produced metric name instead
+	// of replacing unsupported characters with underscores.
+	AllowUTF8 bool
+}
+
+// Translator converts metric names between their OTLP and Prometheus
+// representations. It is the canonical implementation shared by the
+// remote-write receive path and the OTLP export path, so that both agree on
+// how a given metric is named.
+//
+// The zero value is ready to use.
+type Translator struct{}
+
+// NewTranslator returns a Translator ready for use.
+func NewTranslator() Translator {
+	return Translator{}
+}
+
+// ToPrometheusName builds a Prometheus-compliant metric name for the
+// specified OTLP metric. With opts.AddMetricSuffixes set, this follows the
+// same rules as BuildCompliantName; see that function for details.
+func (Translator) ToPrometheusName(metric pmetric.Metric, namespace string, opts Options) string {
+	if opts.UsePrometheusNaming {
+		name := metric.Name()
+		if namespace != "" {
+			name = namespace + "_" + name
+		}
+		return name
+	}
+	return BuildCompliantName(metric, namespace, opts.AddMetricSuffixes, opts.AllowUTF8)
+}
+
+// FromPrometheusName splits a Prometheus metric name into its base name and
+// the OTLP unit it was built from, inverting ToPrometheusName. unit is the
+// Prometheus metric's unit metadata, expressed in the same word form that
+// BuildCompliantName appends to names (e.g. "bytes", "seconds_per_second"),
+// not the original OTLP/UCUM unit.
+//
+// FromPrometheusName is a superset of TrimPromSuffixes: in addition to
+// trimming the recognized type and unit suffixes, it reconstructs the OTLP
+// unit string (e.g. "bytes" -> "By", "seconds" -> "s", "bytes_per_second" ->
+// "By/s"), so that round-tripping a metric through Prometheus does not lose
+// unit information. Suffix trimming is delegated to TrimPromSuffixes so the
+// two can't silently drift apart on edge cases like doubled separators.
+func (Translator) FromPrometheusName(name string, metricType pmetric.MetricType, unit string) (baseName, otlpUnit string) {
+	return TrimPromSuffixes(name, metricType, unit), unitToOTel(unit)
+}
+
+// unitToOTel reconstructs the OTLP/UCUM unit string corresponding to the
+// given Prometheus unit metadata, which is expressed as "<main>" or
+// "<main>_per_<per>" (e.g. "bytes_per_second").
+func unitToOTel(unit string) string {
+	if unit == "" {
+		return ""
+	}
+
+	mainWord, perWord, _ := strings.Cut(unit, "_per_")
+
+	mainOTel := "1"
+	if mainWord != "" {
+		mainOTel = inverseUnitMapGetOrDefault(mainWord)
+	}
+
+	if perWord == "" {
+		return mainOTel
+	}
+
+	return mainOTel + "/" + inversePerUnitMapGetOrDefault(perWord)
+}
+
+// inverseUnitMap and inversePerUnitMap translate Prometheus unit words back
+// to their OTLP/UCUM form, inverting unitMap and perUnitMap respectively.
+// Where multiple UCUM units map to the same word (e.g. "l" and "L" both mean
+// "liters"), the alphabetically first UCUM unit wins, so the inverse is
+// deterministic.
+var (
+	inverseUnitMap    = buildInverseUnitMap(unitMap)
+	inversePerUnitMap = buildInverseUnitMap(perUnitMap)
+)
+
+func buildInverseUnitMap(m map[string]string) map[string]string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	inv := make(map[string]string, len(m))
+	for _, k := range keys {
+		v := m[k]
+		if v == "" {
+			continue
+		}
+		if _, ok := inv[v]; !ok {
+			inv[v] = k
+		}
+	}
+	return inv
+}
+
+func inverseUnitMapGetOrDefault(word string) string {
+	if unit, ok := inverseUnitMap[word]; ok {
+		return unit
+	}
+	return word
+}
+
+func inversePerUnitMapGetOrDefault(word string) string {
+	if unit, ok := inversePerUnitMap[word]; ok {
+		return unit
+	}
+	return word
+}
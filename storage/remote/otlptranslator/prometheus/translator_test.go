@@ -0,0 +1,111 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestTranslatorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		metricName   string
+		otlpUnit     string
+		metricType   pmetric.MetricType
+		monotonic    bool
+		wantPromName string
+		wantBaseName string
+		wantPromUnit string
+		wantOTLPUnit string
+	}{
+		{
+			name:         "bytes gauge",
+			metricName:   "disk_usage",
+			otlpUnit:     "By",
+			metricType:   pmetric.MetricTypeGauge,
+			wantPromName: "disk_usage_bytes",
+			wantBaseName: "disk_usage",
+			wantPromUnit: "bytes",
+			wantOTLPUnit: "By",
+		},
+		{
+			name:         "monotonic sum with seconds unit",
+			metricName:   "request_duration",
+			otlpUnit:     "s",
+			metricType:   pmetric.MetricTypeSum,
+			monotonic:    true,
+			wantPromName: "request_duration_seconds_total",
+			wantBaseName: "request_duration",
+			wantPromUnit: "seconds",
+			wantOTLPUnit: "s",
+		},
+	}
+
+	tr := NewTranslator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := pmetric.NewMetrics()
+			metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+			metric.SetName(tt.metricName)
+			metric.SetUnit(tt.otlpUnit)
+			if tt.metricType == pmetric.MetricTypeSum {
+				metric.SetEmptySum().SetIsMonotonic(tt.monotonic)
+			} else {
+				metric.SetEmptyGauge()
+			}
+
+			promName := tr.ToPrometheusName(metric, "", Options{AddMetricSuffixes: true})
+			require.Equal(t, tt.wantPromName, promName)
+
+			baseName, otlpUnit := tr.FromPrometheusName(promName, tt.metricType, tt.wantPromUnit)
+			require.Equal(t, tt.wantBaseName, baseName)
+			require.Equal(t, tt.wantOTLPUnit, otlpUnit)
+		})
+	}
+}
+
+func TestTranslatorFromPrometheusNamePreservesDoubleUnderscore(t *testing.T) {
+	// FromPrometheusName must agree with TrimPromSuffixes on doubled
+	// separators instead of re-tokenizing independently.
+	tr := NewTranslator()
+	baseName, _ := tr.FromPrometheusName("foo__bar_total", pmetric.MetricTypeSum, "")
+	require.Equal(t, "foo__bar", baseName)
+}
+
+func TestTranslatorToPrometheusNameAllowUTF8(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("café.日本語")
+	metric.SetUnit("s")
+	metric.SetEmptySum().SetIsMonotonic(true)
+
+	tr := NewTranslator()
+	got := tr.ToPrometheusName(metric, "", Options{AddMetricSuffixes: true, AllowUTF8: true})
+	require.Equal(t, "café.日本語_seconds_total", got)
+}
+
+func TestTranslatorUsePrometheusNaming(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("already_prometheus_style")
+	metric.SetUnit("By")
+	metric.SetEmptyGauge()
+
+	tr := NewTranslator()
+	require.Equal(t, "already_prometheus_style", tr.ToPrometheusName(metric, "", Options{UsePrometheusNaming: true}))
+	require.Equal(t, "ns_already_prometheus_style", tr.ToPrometheusName(metric, "ns", Options{UsePrometheusNaming: true}))
+}